@@ -5,21 +5,27 @@ import (
 	"strings"
 
 	"github.com/labstack/echo/v4"
+	"github.com/tigawanna/boxman/service"
 	"github.com/tigawanna/boxman/systemd"
 )
 
 func main() {
+	mgr := service.New()
+
 	e := echo.New()
 	e.GET("/", func(c echo.Context) error {
 		return c.String(http.StatusOK, "Hello, World!")
 	})
 	e.GET("/services", func(c echo.Context) error {
 		partialName := c.QueryParam("name")
-		services := systemd.GetSystemDServices(partialName)
+		services, err := mgr.List(partialName)
+		if err != nil {
+			return c.String(http.StatusInternalServerError, err.Error())
+		}
 		return c.JSON(http.StatusOK, services)
 	})
 	e.GET("/new", func(c echo.Context) error {
-		config := systemd.NewSystemdServiceConfig(
+		config, err := systemd.NewSystemdServiceConfig(
 			"pocketbase",
 			"~/pb",
 			"pocketbase serve yourdomain.com",
@@ -28,8 +34,14 @@ func main() {
 				Group: "pocketbase",
 			},
 		)
-		// fmt.Println(config.ToString())
-		return c.String(http.StatusOK, config.ToString())
+		if err != nil {
+			return c.String(http.StatusInternalServerError, err.Error())
+		}
+		rendered, err := config.ToString()
+		if err != nil {
+			return c.String(http.StatusInternalServerError, err.Error())
+		}
+		return c.String(http.StatusOK, rendered)
 	})
 	e.POST("/service/new", func(c echo.Context) error {
 		name := c.FormValue("name")
@@ -45,17 +57,111 @@ func main() {
 		if len(path) >= 2 && path[:2] != "~/" {
 			return c.String(http.StatusBadRequest, "path must be absolute, try ~/path/to/service")
 		}
-		config := systemd.NewSystemdServiceConfig(
-			"pocketbase",
-			"~/pb",
-			"pocketbase serve yourdomain.com",
-			&systemd.ConfigOptions{
-				User:  "pocketbase",
-				Group: "pocketbase",
-			},
-		)
-		// fmt.Println(config.ToString())
-		return c.String(http.StatusOK, config.ToString())
+
+		scope := service.Scope(c.FormValue("scope"))
+		var users []string
+		if raw := c.FormValue("users"); raw != "" {
+			for _, u := range strings.Split(raw, ",") {
+				if u = strings.TrimSpace(u); u != "" {
+					users = append(users, u)
+				}
+			}
+		}
+
+		execStart := c.FormValue("exec")
+		if execStart == "" {
+			execStart = "pocketbase serve yourdomain.com"
+		}
+
+		cfg := service.Config{
+			Name:       name,
+			WorkingDir: path,
+			ExecStart:  execStart,
+			User:       "pocketbase",
+			Group:      "pocketbase",
+			Restart:    true,
+			Scope:      scope,
+			Users:      users,
+		}
+
+		if err := mgr.Install(cfg); err != nil {
+			return c.String(http.StatusBadRequest, err.Error())
+		}
+
+		return c.String(http.StatusOK, "installed "+name)
+	})
+	e.POST("/service/:name/dropin", func(c echo.Context) error {
+		unitName := c.Param("name")
+		if !strings.HasSuffix(unitName, ".service") {
+			unitName += ".service"
+		}
+
+		var body struct {
+			Section  string `json:"section"`
+			Key      string `json:"key"`
+			Value    string `json:"value"`
+			Priority int    `json:"priority"`
+		}
+		if err := c.Bind(&body); err != nil {
+			return c.String(http.StatusBadRequest, err.Error())
+		}
+		if body.Section == "" || body.Key == "" {
+			return c.String(http.StatusBadRequest, "section and key are required")
+		}
+
+		dropIn := systemd.NewDropIn(unitName, body.Section, map[string]string{body.Key: body.Value})
+		if body.Priority != 0 {
+			dropIn.Priority = body.Priority
+		}
+
+		paths, err := dropIn.Write(c.Request().Context())
+		if err != nil {
+			return c.String(http.StatusInternalServerError, err.Error())
+		}
+
+		return c.JSON(http.StatusOK, paths)
+	})
+	e.POST("/services/preset", func(c echo.Context) error {
+		var body struct {
+			Priority int `json:"priority"`
+			Units    []struct {
+				Unit      string   `json:"unit"`
+				Action    string   `json:"action"`
+				Instances []string `json:"instances"`
+			} `json:"units"`
+		}
+		if err := c.Bind(&body); err != nil {
+			return c.String(http.StatusBadRequest, err.Error())
+		}
+		if len(body.Units) == 0 {
+			return c.String(http.StatusBadRequest, "units is required")
+		}
+
+		presets := make([]systemd.Preset, 0, len(body.Units))
+		unitNames := make([]string, 0, len(body.Units))
+		for _, u := range body.Units {
+			action := systemd.PresetAction(u.Action)
+			if action == "" {
+				action = systemd.PresetEnable
+			}
+			presets = append(presets, systemd.Preset{
+				Unit:      u.Unit,
+				Action:    action,
+				Instances: u.Instances,
+			})
+			unitNames = append(unitNames, u.Unit)
+		}
+
+		path := systemd.DefaultPresetPath(body.Priority)
+		if err := systemd.WritePresetFile(presets, path); err != nil {
+			return c.String(http.StatusInternalServerError, err.Error())
+		}
+
+		if err := systemd.ApplyPresets(c.Request().Context(), unitNames); err != nil {
+			return c.String(http.StatusInternalServerError, err.Error())
+		}
+
+		return c.String(http.StatusOK, path)
 	})
 	e.Logger.Fatal(e.Start(":1323"))
 }
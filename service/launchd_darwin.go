@@ -0,0 +1,210 @@
+//go:build darwin
+
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// New returns the macOS Manager backend, which wraps launchd.
+func New() Manager {
+	return launchdManager{}
+}
+
+type launchdManager struct{}
+
+var plistTemplate = template.Must(template.New("plist").Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>{{.Label}}</string>
+	<key>ProgramArguments</key>
+	<array>
+		<string>/bin/sh</string>
+		<string>-c</string>
+		<string>{{.ExecStart}}</string>
+	</array>
+	<key>WorkingDirectory</key>
+	<string>{{.WorkingDir}}</string>
+	<key>KeepAlive</key>
+	<{{if .Restart}}true{{else}}false{{end}}/>
+	<key>RunAtLoad</key>
+	<true/>
+</dict>
+</plist>
+`))
+
+// plistPath returns the install path for a launchd job, following Apple's
+// convention of LaunchAgents for per-user jobs and LaunchDaemons for
+// system-wide ones.
+func plistPath(cfg Config) (string, error) {
+	label := "com.boxman." + cfg.Name
+	if cfg.Scope == ScopeUser {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("service: resolving home dir: %w", err)
+		}
+		return filepath.Join(home, "Library", "LaunchAgents", label+".plist"), nil
+	}
+	return filepath.Join("/Library", "LaunchDaemons", label+".plist"), nil
+}
+
+func (launchdManager) Install(cfg Config) error {
+	// A LaunchAgent only ever installs into the calling process's own
+	// gui/<uid> session - there's no launchctl bootstrap domain for
+	// installing into another user's session without that user's own
+	// login context, so a request naming other users can't be honored.
+	if cfg.Scope == ScopeUser && len(cfg.Users) > 0 {
+		return fmt.Errorf("service: user-scope install for %q targets %d user(s), but the launchd backend can only install into the calling user's session", cfg.Name, len(cfg.Users))
+	}
+
+	path, err := plistPath(cfg)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("service: creating %s: %w", filepath.Dir(path), err)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("service: creating %s: %w", path, err)
+	}
+	defer f.Close()
+
+	label := "com.boxman." + cfg.Name
+	if err := plistTemplate.Execute(f, struct {
+		Label      string
+		ExecStart  string
+		WorkingDir string
+		Restart    bool
+	}{label, cfg.ExecStart, cfg.WorkingDir, cfg.Restart}); err != nil {
+		return fmt.Errorf("service: rendering %s: %w", path, err)
+	}
+
+	return exec.Command("launchctl", "bootstrap", launchdDomain(cfg.Scope), path).Run()
+}
+
+func (launchdManager) Uninstall(t Target) error {
+	label := "com.boxman." + t.Name
+	if err := exec.Command("launchctl", "bootout", domainForLabel(label)+"/"+label).Run(); err != nil {
+		return fmt.Errorf("service: bootout %q: %w", t.Name, err)
+	}
+	path, err := plistPath(Config{Name: t.Name, Scope: t.Scope})
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("service: removing %s: %w", path, err)
+	}
+	return nil
+}
+
+func (launchdManager) Start(t Target) error {
+	label := "com.boxman." + t.Name
+	return exec.Command("launchctl", "kickstart", "-k", domainForLabel(label)+"/"+label).Run()
+}
+
+func (launchdManager) Stop(t Target) error {
+	label := "com.boxman." + t.Name
+	return exec.Command("launchctl", "bootout", domainForLabel(label)+"/"+label).Run()
+}
+
+func (launchdManager) Restart(t Target) error {
+	label := "com.boxman." + t.Name
+	return exec.Command("launchctl", "kickstart", "-k", domainForLabel(label)+"/"+label).Run()
+}
+
+func (launchdManager) Status(t Target) (State, error) {
+	label := "com.boxman." + t.Name
+	out, err := exec.Command("launchctl", "print", domainForLabel(label)+"/"+label).CombinedOutput()
+	if err != nil {
+		return StateStopped, nil
+	}
+	if contains(out, "state = running") {
+		return StateRunning, nil
+	}
+	return StateUnknown, nil
+}
+
+func (launchdManager) List(filter string) ([]Service, error) {
+	out, err := exec.Command("launchctl", "list").CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("service: launchctl list: %w", err)
+	}
+	return parseLaunchctlList(out, filter), nil
+}
+
+func launchdDomain(scope Scope) string {
+	if scope == ScopeUser {
+		return "gui/" + currentUID()
+	}
+	return "system"
+}
+
+// domainForLabel determines which launchd domain a previously-installed job
+// actually lives in, by checking whether its plist was written to the
+// per-user LaunchAgents directory (see plistPath). Without this, every
+// control operation after Install would hardcode "gui/<uid>" even for the
+// default ScopeSystem install, which lands in /Library/LaunchDaemons under
+// the "system" domain and would never be found.
+func domainForLabel(label string) string {
+	if home, err := os.UserHomeDir(); err == nil {
+		agentPath := filepath.Join(home, "Library", "LaunchAgents", label+".plist")
+		if _, err := os.Stat(agentPath); err == nil {
+			return "gui/" + currentUID()
+		}
+	}
+	return "system"
+}
+
+// currentUID returns the calling process's UID, used to address the launchd
+// "gui/<uid>" domain for the boxman process's own user session.
+func currentUID() string {
+	if u, err := user.Current(); err == nil {
+		return u.Uid
+	}
+	return "0"
+}
+
+func contains(haystack []byte, needle string) bool {
+	return bytes.Contains(haystack, []byte(needle))
+}
+
+// parseLaunchctlList parses the tab-delimited `launchctl list` table
+// (PID, status, label) into Services, optionally filtered to labels
+// containing filter.
+func parseLaunchctlList(output []byte, filter string) []Service {
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	services := []Service{}
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "PID") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			continue
+		}
+		label := fields[2]
+		if filter != "" && !strings.Contains(label, filter) {
+			continue
+		}
+		state := StateStopped
+		if fields[0] != "-" {
+			state = StateRunning
+		}
+		services = append(services, Service{Name: label, State: state})
+	}
+	return services
+}
@@ -0,0 +1,83 @@
+// Package service defines a cross-platform service-management interface and
+// selects a concrete backend (systemd on Linux, launchd on macOS, the
+// Windows Service Control Manager on Windows) at compile time via build
+// tags. Callers depend only on Manager and never reference a specific init
+// system directly.
+package service
+
+// Scope controls which service-manager instance a unit is installed into:
+// the machine-wide manager (system), a single user's own manager (user), or
+// a manager-wide definition available to every user without a per-user copy
+// (global). Not every backend honors every scope distinction (Windows has no
+// systemd-style global scope, for instance), but each maps it to the closest
+// native equivalent.
+type Scope string
+
+const (
+	ScopeSystem Scope = "system"
+	ScopeUser   Scope = "user"
+	ScopeGlobal Scope = "global"
+)
+
+// State is the normalized run state of a managed service, independent of the
+// backend's own vocabulary (e.g. systemd's active/inactive/failed or
+// launchd's running/not running).
+type State string
+
+const (
+	StateRunning State = "running"
+	StateStopped State = "stopped"
+	StateFailed  State = "failed"
+	StateUnknown State = "unknown"
+)
+
+// Config describes a service to install, independent of the backend that
+// will ultimately render and register it.
+type Config struct {
+	Name        string
+	Description string
+	ExecStart   string
+	WorkingDir  string
+	User        string
+	Group       string
+	Restart     bool
+	Env         map[string]string
+
+	// Scope selects the manager instance. Defaults to ScopeSystem.
+	Scope Scope
+	// Users is the list of target users. The systemd backend requires at
+	// least one for ScopeUser and rejects Users for other scopes; the
+	// launchd and Windows backends install ScopeUser services only for the
+	// calling user and reject a non-empty Users for that scope.
+	Users []string
+}
+
+// Service is one entry returned by Manager.List.
+type Service struct {
+	Name  string
+	State State
+}
+
+// Target identifies a previously-installed service for a control operation.
+// Scope and Users must match what the service was Install'd with — a
+// ScopeUser service lives in a different manager instance per user, so
+// Uninstall/Start/Stop/Restart/Status need the same Scope/Users Install saw
+// in order to reach it.
+type Target struct {
+	Name  string
+	Scope Scope
+	Users []string
+}
+
+// Manager installs, removes, and controls services on the host's native
+// service manager. One implementation is selected per platform by build
+// tag; construct it with New.
+type Manager interface {
+	Install(cfg Config) error
+	Uninstall(t Target) error
+	Start(t Target) error
+	Stop(t Target) error
+	Restart(t Target) error
+	Status(t Target) (State, error)
+	List(filter string) ([]Service, error)
+}
@@ -0,0 +1,213 @@
+//go:build linux
+
+package service
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+
+	"github.com/tigawanna/boxman/systemd"
+)
+
+// New returns the Linux Manager backend, which wraps the systemd package.
+func New() Manager {
+	return systemdManager{}
+}
+
+type systemdManager struct{}
+
+func restartPolicy(restart bool) string {
+	if restart {
+		return "always"
+	}
+	return "no"
+}
+
+func (systemdManager) Install(cfg Config) error {
+	svcCfg, err := systemd.NewSystemdServiceConfig(cfg.Name, cfg.WorkingDir, cfg.ExecStart, &systemd.ConfigOptions{
+		Type:        "simple",
+		User:        cfg.User,
+		Group:       cfg.Group,
+		LimitNOFILE: 4096,
+		Restart:     restartPolicy(cfg.Restart),
+		RestartSec:  "5s",
+		Scope:       systemd.Scope(cfg.Scope),
+		Users:       cfg.Users,
+	})
+	if err != nil {
+		return fmt.Errorf("service: building unit for %q: %w", cfg.Name, err)
+	}
+
+	if err := svcCfg.Validate(); err != nil {
+		return fmt.Errorf("service: validating unit for %q: %w", cfg.Name, err)
+	}
+
+	rendered, err := svcCfg.ToString()
+	if err != nil {
+		return fmt.Errorf("service: rendering unit for %q: %w", cfg.Name, err)
+	}
+
+	paths := svcCfg.Paths
+	if svcCfg.Path != "" {
+		paths = []string{svcCfg.Path}
+	}
+	for _, path := range paths {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return fmt.Errorf("service: creating %s: %w", filepath.Dir(path), err)
+		}
+		if err := os.WriteFile(path, []byte(rendered), 0o644); err != nil {
+			return fmt.Errorf("service: writing %s: %w", path, err)
+		}
+	}
+
+	// A ScopeUser unit is invisible to each target user's own systemd
+	// instance until that instance reloads, which the system-wide
+	// `systemctl daemon-reload` below never reaches.
+	if svcCfg.Scope == systemd.ScopeUser {
+		for _, username := range cfg.Users {
+			if _, err := systemd.RunUserSystemctl(username, "daemon-reload"); err != nil {
+				return fmt.Errorf("service: reloading user %q systemd: %w", username, err)
+			}
+		}
+		return nil
+	}
+
+	return exec.Command("systemctl", "daemon-reload").Run()
+}
+
+// runVerb runs `systemctl <verb> name` for a ScopeSystem/ScopeGlobal target,
+// or the --user equivalent against each of t.Users for a ScopeUser target -
+// the same distinction Install already makes when writing and reloading the
+// unit.
+func (systemdManager) runVerb(t Target, verb string) error {
+	if t.Scope == ScopeUser {
+		for _, username := range t.Users {
+			if _, err := systemd.RunUserSystemctl(username, verb, t.Name); err != nil {
+				return fmt.Errorf("service: %s %q for user %q: %w", verb, t.Name, username, err)
+			}
+		}
+		return nil
+	}
+	return exec.Command("systemctl", verb, t.Name).Run()
+}
+
+func (systemdManager) Uninstall(t Target) error {
+	var err error
+	if t.Scope == ScopeGlobal {
+		err = exec.Command("systemctl", "--global", "disable", t.Name).Run()
+	} else {
+		err = (systemdManager{}).runVerb(t, "disable")
+	}
+	if err != nil {
+		return fmt.Errorf("service: disabling %q: %w", t.Name, err)
+	}
+
+	if t.Scope == ScopeUser {
+		for _, username := range t.Users {
+			u, err := user.Lookup(username)
+			if err != nil {
+				return fmt.Errorf("service: resolving home dir for user %q: %w", username, err)
+			}
+			path := filepath.Join(u.HomeDir, ".config", "systemd", "user", t.Name+".service")
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("service: removing %s: %w", path, err)
+			}
+			if _, err := systemd.RunUserSystemctl(username, "daemon-reload"); err != nil {
+				return fmt.Errorf("service: reloading user %q systemd: %w", username, err)
+			}
+		}
+		return nil
+	}
+
+	dir := "/lib/systemd/system/"
+	if t.Scope == ScopeGlobal {
+		dir = "/etc/systemd/user/"
+	}
+	path := dir + t.Name + ".service"
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("service: removing %s: %w", path, err)
+	}
+	return exec.Command("systemctl", "daemon-reload").Run()
+}
+
+func (systemdManager) Start(t Target) error {
+	return (systemdManager{}).runVerb(t, "start")
+}
+
+func (systemdManager) Stop(t Target) error {
+	return (systemdManager{}).runVerb(t, "stop")
+}
+
+func (systemdManager) Restart(t Target) error {
+	return (systemdManager{}).runVerb(t, "restart")
+}
+
+func (systemdManager) Status(t Target) (State, error) {
+	var out []byte
+	var err error
+	if t.Scope == ScopeUser {
+		if len(t.Users) == 0 {
+			return StateUnknown, fmt.Errorf("service: status for %q requires at least one user", t.Name)
+		}
+		// Each target user has their own instance; report the first
+		// one's state, matching the one-state-per-call shape of Status.
+		out, err = systemd.RunUserSystemctl(t.Users[0], "is-active", t.Name)
+	} else {
+		out, err = exec.Command("systemctl", "is-active", t.Name).CombinedOutput()
+	}
+
+	state := trimNewline(string(out))
+	// `systemctl is-active` exits non-zero for every state but "active", so
+	// a non-nil err here doesn't by itself mean the query failed - only
+	// treat it as a real failure when there's no state text to fall back
+	// on (e.g. systemctl itself couldn't be invoked).
+	if state == "" && err != nil {
+		return StateUnknown, fmt.Errorf("service: querying status of %q: %w", t.Name, err)
+	}
+
+	switch state {
+	case "active":
+		return StateRunning, nil
+	case "inactive":
+		return StateStopped, nil
+	case "failed":
+		return StateFailed, nil
+	default:
+		return StateUnknown, nil
+	}
+}
+
+func (systemdManager) List(filter string) ([]Service, error) {
+	units := systemd.GetSystemDServices(filter)
+	services := make([]Service, 0, len(units))
+	for _, u := range units {
+		services = append(services, Service{
+			Name:  u.Name,
+			State: mapActiveState(u.ActiveState),
+		})
+	}
+	return services, nil
+}
+
+func mapActiveState(activeState string) State {
+	switch activeState {
+	case "active":
+		return StateRunning
+	case "inactive":
+		return StateStopped
+	case "failed":
+		return StateFailed
+	default:
+		return StateUnknown
+	}
+}
+
+func trimNewline(s string) string {
+	for len(s) > 0 && (s[len(s)-1] == '\n' || s[len(s)-1] == '\r') {
+		s = s[:len(s)-1]
+	}
+	return s
+}
@@ -0,0 +1,215 @@
+//go:build windows
+
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/sys/windows/registry"
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+// New returns the Windows Manager backend. System-scoped services are
+// created as real Windows services via the Service Control Manager;
+// user-scoped services fall back to an HKCU Run key, since Windows has no
+// per-user equivalent of a service manager.
+func New() Manager {
+	return windowsManager{}
+}
+
+type windowsManager struct{}
+
+func (windowsManager) Install(cfg Config) error {
+	if cfg.Scope == ScopeUser {
+		// HKCU only reaches the account boxman itself is running as -
+		// there's no Windows equivalent of `sudo -u` for the registry, so
+		// a request naming other users can't be honored correctly.
+		if len(cfg.Users) > 0 {
+			return fmt.Errorf("service: user-scope install for %q targets %d user(s), but the Windows Run-key backend can only install for the calling user", cfg.Name, len(cfg.Users))
+		}
+		return installRunKey(cfg)
+	}
+
+	m, err := mgr.Connect()
+	if err != nil {
+		return fmt.Errorf("service: connecting to SCM: %w", err)
+	}
+	defer m.Disconnect()
+
+	startType := uint32(mgr.StartManual)
+	if cfg.Restart {
+		startType = mgr.StartAutomatic
+	}
+
+	binary, args := splitExecStart(cfg.ExecStart)
+	s, err := m.CreateService(cfg.Name, binary, mgr.Config{
+		DisplayName: cfg.Name,
+		Description: cfg.Description,
+		StartType:   startType,
+	}, args...)
+	if err != nil {
+		return fmt.Errorf("service: creating %q: %w", cfg.Name, err)
+	}
+	defer s.Close()
+
+	return nil
+}
+
+// splitExecStart splits a command line like "C:\pb\pocketbase.exe serve
+// example.com" into the binary path CreateService expects as exepath and
+// the remaining words as its args, the same split systemd leaves to the
+// shell when it parses ExecStart= itself.
+func splitExecStart(execStart string) (binary string, args []string) {
+	fields := strings.Fields(execStart)
+	if len(fields) == 0 {
+		return "", nil
+	}
+	return fields[0], fields[1:]
+}
+
+func (windowsManager) Uninstall(t Target) error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return fmt.Errorf("service: connecting to SCM: %w", err)
+	}
+	defer m.Disconnect()
+
+	s, err := m.OpenService(t.Name)
+	if err != nil {
+		return deleteRunKey(t.Name)
+	}
+	defer s.Close()
+
+	return s.Delete()
+}
+
+func (windowsManager) Start(t Target) error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return fmt.Errorf("service: connecting to SCM: %w", err)
+	}
+	defer m.Disconnect()
+
+	s, err := m.OpenService(t.Name)
+	if err != nil {
+		return fmt.Errorf("service: opening %q: %w", t.Name, err)
+	}
+	defer s.Close()
+
+	return s.Start()
+}
+
+func (windowsManager) Stop(t Target) error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return fmt.Errorf("service: connecting to SCM: %w", err)
+	}
+	defer m.Disconnect()
+
+	s, err := m.OpenService(t.Name)
+	if err != nil {
+		return fmt.Errorf("service: opening %q: %w", t.Name, err)
+	}
+	defer s.Close()
+
+	_, err = s.Control(svc.Stop)
+	return err
+}
+
+func (windowsManager) Restart(t Target) error {
+	if err := (windowsManager{}).Stop(t); err != nil {
+		return err
+	}
+	return (windowsManager{}).Start(t)
+}
+
+func (windowsManager) Status(t Target) (State, error) {
+	m, err := mgr.Connect()
+	if err != nil {
+		return StateUnknown, fmt.Errorf("service: connecting to SCM: %w", err)
+	}
+	defer m.Disconnect()
+
+	s, err := m.OpenService(t.Name)
+	if err != nil {
+		return StateUnknown, fmt.Errorf("service: opening %q: %w", t.Name, err)
+	}
+	defer s.Close()
+
+	status, err := s.Query()
+	if err != nil {
+		return StateUnknown, fmt.Errorf("service: querying %q: %w", t.Name, err)
+	}
+
+	switch status.State {
+	case svc.Running:
+		return StateRunning, nil
+	case svc.Stopped:
+		return StateStopped, nil
+	default:
+		return StateUnknown, nil
+	}
+}
+
+func (windowsManager) List(filter string) ([]Service, error) {
+	m, err := mgr.Connect()
+	if err != nil {
+		return nil, fmt.Errorf("service: connecting to SCM: %w", err)
+	}
+	defer m.Disconnect()
+
+	names, err := m.ListServices()
+	if err != nil {
+		return nil, fmt.Errorf("service: listing services: %w", err)
+	}
+
+	services := make([]Service, 0, len(names))
+	for _, name := range names {
+		if filter != "" && !contains(name, filter) {
+			continue
+		}
+		state, err := (windowsManager{}).Status(Target{Name: name})
+		if err != nil {
+			state = StateUnknown
+		}
+		services = append(services, Service{Name: name, State: state})
+	}
+	return services, nil
+}
+
+func contains(s, substr string) bool {
+	return len(substr) == 0 || (len(s) >= len(substr) && indexOf(s, substr) >= 0)
+}
+
+func indexOf(s, substr string) int {
+	for i := 0; i+len(substr) <= len(s); i++ {
+		if s[i:i+len(substr)] == substr {
+			return i
+		}
+	}
+	return -1
+}
+
+const runKeyPath = `Software\Microsoft\Windows\CurrentVersion\Run`
+
+func installRunKey(cfg Config) error {
+	key, _, err := registry.CreateKey(registry.CURRENT_USER, runKeyPath, registry.SET_VALUE)
+	if err != nil {
+		return fmt.Errorf("service: opening HKCU Run key: %w", err)
+	}
+	defer key.Close()
+
+	return key.SetStringValue(cfg.Name, cfg.ExecStart)
+}
+
+func deleteRunKey(name string) error {
+	key, err := registry.OpenKey(registry.CURRENT_USER, runKeyPath, registry.SET_VALUE)
+	if err != nil {
+		return fmt.Errorf("service: opening HKCU Run key: %w", err)
+	}
+	defer key.Close()
+
+	return key.DeleteValue(name)
+}
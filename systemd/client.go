@@ -0,0 +1,194 @@
+package systemd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+)
+
+// Client wraps a systemd D-Bus connection. It replaces the old
+// exec.Command("systemctl", ...) + text-scraping approach with the real
+// systemd API, so unit names, states, and job results come back as typed
+// values instead of whitespace-delimited columns that break on localized
+// output, truncated ("…") unit names, and concurrent callers.
+type Client struct {
+	conn *dbus.Conn
+}
+
+// NewClient opens a connection to the systemd D-Bus API. Callers must call
+// Close when done.
+func NewClient(ctx context.Context) (*Client, error) {
+	conn, err := dbus.NewWithContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("systemd: connecting to D-Bus: %w", err)
+	}
+	return &Client{conn: conn}, nil
+}
+
+// Close releases the underlying D-Bus connection.
+func (c *Client) Close() {
+	c.conn.Close()
+}
+
+// Reload tells systemd to re-read unit files on disk, the D-Bus equivalent
+// of `systemctl daemon-reload`. Callers that write or rewrite drop-ins must
+// call this for the change to take effect.
+func (c *Client) Reload(ctx context.Context) error {
+	if err := c.conn.ReloadContext(ctx); err != nil {
+		return fmt.Errorf("systemd: daemon-reload: %w", err)
+	}
+	return nil
+}
+
+// ListUnits lists active service units, optionally filtered to names
+// containing partialName. This is the D-Bus-backed replacement for
+// GetSystemDServices, used when a Client is available; GetSystemDServices
+// itself keeps the exec.Command fallback for environments (e.g. minimal
+// containers) where the D-Bus socket isn't reachable.
+func (c *Client) ListUnits(ctx context.Context, partialName string) ([]Service, error) {
+	patterns := []string{"*.service"}
+	if partialName != "" {
+		patterns = []string{"*" + partialName + "*.service"}
+	}
+
+	// Match GetSystemDServices's documented contract (and its own
+	// systemctl fallback, --state=active) by only listing active units.
+	units, err := c.conn.ListUnitsByPatternsContext(ctx, []string{"active"}, patterns)
+	if err != nil {
+		return nil, fmt.Errorf("systemd: listing units: %w", err)
+	}
+
+	services := make([]Service, 0, len(units))
+	for _, u := range units {
+		services = append(services, Service{
+			Name:        u.Name,
+			Unit:        u.Name,
+			ActiveState: u.ActiveState,
+			SubState:    u.SubState,
+			LoadState:   u.LoadState,
+			Path:        "/etc/systemd/system/" + u.Name,
+		})
+	}
+	return services, nil
+}
+
+// waitForJob blocks until a StartUnit/StopUnit/RestartUnit-style job
+// completes, returning an error if it didn't finish with "done".
+func waitForJob(ch chan string) error {
+	result := <-ch
+	if result != "done" {
+		return fmt.Errorf("systemd: job finished with result %q", result)
+	}
+	return nil
+}
+
+// StartUnit starts unit and waits for the job to complete.
+func (c *Client) StartUnit(ctx context.Context, unit string) error {
+	ch := make(chan string, 1)
+	if _, err := c.conn.StartUnitContext(ctx, unit, "replace", ch); err != nil {
+		return fmt.Errorf("systemd: starting %q: %w", unit, err)
+	}
+	return waitForJob(ch)
+}
+
+// StopUnit stops unit and waits for the job to complete.
+func (c *Client) StopUnit(ctx context.Context, unit string) error {
+	ch := make(chan string, 1)
+	if _, err := c.conn.StopUnitContext(ctx, unit, "replace", ch); err != nil {
+		return fmt.Errorf("systemd: stopping %q: %w", unit, err)
+	}
+	return waitForJob(ch)
+}
+
+// RestartUnit restarts unit and waits for the job to complete.
+func (c *Client) RestartUnit(ctx context.Context, unit string) error {
+	ch := make(chan string, 1)
+	if _, err := c.conn.RestartUnitContext(ctx, unit, "replace", ch); err != nil {
+		return fmt.Errorf("systemd: restarting %q: %w", unit, err)
+	}
+	return waitForJob(ch)
+}
+
+// EnableUnitFiles enables the named unit files, paralleling `systemctl
+// enable`.
+func (c *Client) EnableUnitFiles(ctx context.Context, units []string, runtime, force bool) error {
+	_, _, err := c.conn.EnableUnitFilesContext(ctx, units, runtime, force)
+	if err != nil {
+		return fmt.Errorf("systemd: enabling %v: %w", units, err)
+	}
+	return nil
+}
+
+// DisableUnitFiles disables the named unit files, paralleling `systemctl
+// disable`.
+func (c *Client) DisableUnitFiles(ctx context.Context, units []string, runtime bool) error {
+	_, err := c.conn.DisableUnitFilesContext(ctx, units, runtime)
+	if err != nil {
+		return fmt.Errorf("systemd: disabling %v: %w", units, err)
+	}
+	return nil
+}
+
+// MaskUnitFiles masks the named unit files, paralleling `systemctl mask`.
+func (c *Client) MaskUnitFiles(ctx context.Context, units []string, runtime, force bool) error {
+	_, err := c.conn.MaskUnitFilesContext(ctx, units, runtime, force)
+	if err != nil {
+		return fmt.Errorf("systemd: masking %v: %w", units, err)
+	}
+	return nil
+}
+
+// JobEvent reports a change in a unit's state, for streaming progress
+// (e.g. over SSE/WebSocket) back to an HTTP caller that kicked off a
+// long-running start/stop/restart. Result holds the unit's new ActiveState
+// (e.g. "active" or "failed"), not the result of a systemd job.
+type JobEvent struct {
+	Unit   string
+	Result string
+}
+
+// SubscribeToJobs streams unit state changes until ctx is canceled, one
+// JobEvent per changed unit from the D-Bus unit subscription; it does not
+// report systemd job results. The returned channel is closed when the
+// subscription ends; a second error channel reports subscription failures.
+func (c *Client) SubscribeToJobs(ctx context.Context) (<-chan JobEvent, <-chan error) {
+	events := make(chan JobEvent)
+	errs := make(chan error, 1)
+
+	if err := c.conn.Subscribe(); err != nil {
+		errs <- fmt.Errorf("systemd: subscribing to D-Bus signals: %w", err)
+		close(events)
+		return events, errs
+	}
+	unitChanges, subErrs := c.conn.SubscribeUnits(1)
+
+	go func() {
+		defer close(events)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case err := <-subErrs:
+				if err != nil {
+					select {
+					case errs <- err:
+					default:
+					}
+				}
+			case changes, ok := <-unitChanges:
+				if !ok {
+					return
+				}
+				for name, status := range changes {
+					if status == nil {
+						continue
+					}
+					events <- JobEvent{Unit: name, Result: status.ActiveState}
+				}
+			}
+		}
+	}()
+
+	return events, errs
+}
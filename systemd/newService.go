@@ -3,10 +3,27 @@ package systemd
 import (
 	"fmt"
 	"os"
+	"os/user"
 	"path/filepath"
 	"strings"
 )
 
+// Scope controls which systemd manager instance a unit is installed into and,
+// as a result, where its unit file is written on disk.
+type Scope string
+
+const (
+	// ScopeSystem installs into the system manager (PID 1) under
+	// /lib/systemd/system. This is the historical boxman default.
+	ScopeSystem Scope = "system"
+	// ScopeUser installs into the per-user manager of one or more target
+	// users under ~/.config/systemd/user.
+	ScopeUser Scope = "user"
+	// ScopeGlobal installs into /etc/systemd/user, where it is picked up by
+	// every user's manager without being copied into each home directory.
+	ScopeGlobal Scope = "global"
+)
+
 type SystemdServiceConfig struct {
 	// Unit section
 	Unit struct {
@@ -30,7 +47,29 @@ type SystemdServiceConfig struct {
 	Install struct {
 		WantedBy string
 	}
+
+	// Scope is which systemd manager instance the unit is installed into.
+	// Defaults to ScopeSystem.
+	Scope Scope
+	// Users is the list of target users the unit is installed for. Only
+	// valid when Scope is ScopeUser.
+	Users []string
+
+	// Path is the install path for ScopeSystem and ScopeGlobal units.
 	Path string
+	// Paths is the per-user install paths for ScopeUser units, one entry
+	// per user in Users, expanded from that user's home directory.
+	Paths []string
+
+	// Extra holds arbitrary additional directives, keyed by section then
+	// key, e.g. Extra["Service"]["Environment"]. Repeated keys (like
+	// Environment= or ExecStartPre=) are preserved by listing multiple
+	// values for the same key. These are rendered alongside the typed
+	// Unit/SystemdService/Install fields above and are the only way to
+	// express directives this struct doesn't otherwise model. Only the
+	// "Unit", "Service" and "Install" sections are rendered; entries under
+	// any other section are ignored.
+	Extra map[string]map[string][]string
 }
 
 // ConfigOptions allows overriding default service settings
@@ -73,11 +112,37 @@ type ConfigOptions struct {
 	LimitNOFILE int
 	Restart     string
 	RestartSec  string
+	// Scope selects the systemd manager instance the unit is installed
+	// into. Defaults to ScopeSystem when empty.
+	Scope Scope
+	// Users is the list of target users to install for. Required when
+	// Scope is ScopeUser, and invalid otherwise.
+	Users []string
 }
 
-// NewServiceConfig generates a SystemdServiceConfig for the given service name,
-// base directory and exec command. The opts parameter allows overriding default
-// service settings. If opts is nil, default options are used.
+// validateScope mirrors the rules ignition applies to its systemd unit
+// config: users is only meaningful for user-scoped units, and a user-scoped
+// unit without any target users can never be installed anywhere.
+func validateScope(scope Scope, users []string) error {
+	switch scope {
+	case ScopeUser:
+		if len(users) == 0 {
+			return fmt.Errorf("systemd: scope %q requires at least one user", ScopeUser)
+		}
+	case ScopeSystem, ScopeGlobal, "":
+		if len(users) > 0 {
+			return fmt.Errorf("systemd: users is only valid when scope is %q", ScopeUser)
+		}
+	default:
+		return fmt.Errorf("systemd: unknown scope %q", scope)
+	}
+	return nil
+}
+
+// NewSystemdServiceConfig generates a SystemdServiceConfig for the given
+// service name, base directory and exec command. The opts parameter allows
+// overriding default service settings. If opts is nil, default options are
+// used.
 //
 // The base directory is expanded if it starts with ~ and is ensured to be an
 // absolute path. The log file is created in the base directory under the
@@ -94,13 +159,27 @@ type ConfigOptions struct {
 //   - StandardOutput: append:baseDir/logs/service.log
 //   - StandardError: append:baseDir/logs/service.log
 //   - ExecStart: baseDir/execCommand
-//   - WantedBy: multi-user.target
+//   - Scope: system, WantedBy: multi-user.target
+//
+// opts.Scope selects which systemd manager instance the unit targets:
+//
+//   - ScopeSystem (default): Path is /lib/systemd/system/<name>.service and
+//     WantedBy stays multi-user.target.
+//   - ScopeUser: Path is left empty and Paths holds one
+//     ~/.config/systemd/user/<name>.service entry per user in opts.Users,
+//     expanded from that user's home directory, and WantedBy becomes
+//     default.target.
+//   - ScopeGlobal: Path is /etc/systemd/user/<name>.service and WantedBy
+//     becomes default.target.
+//
+// An error is returned if opts.Users is set for a scope other than
+// ScopeUser, or if ScopeUser is requested with no users.
 //
 // Example usage:
 //
 //		package main
 //
-//	config := NewServiceConfig(
+//	config, err := NewSystemdServiceConfig(
 //		"my-node-server",
 //		"~/my-node-server",
 //		"node /dist/index.js",
@@ -112,7 +191,7 @@ type ConfigOptions struct {
 //	fmt.Println(config.ToString())
 //
 // )
-func NewSystemdServiceConfig(serviceName, baseDir, execCommand string, opts *ConfigOptions) SystemdServiceConfig {
+func NewSystemdServiceConfig(serviceName, baseDir, execCommand string, opts *ConfigOptions) (SystemdServiceConfig, error) {
 	// Default options
 	if opts == nil {
 		opts = &ConfigOptions{
@@ -125,6 +204,15 @@ func NewSystemdServiceConfig(serviceName, baseDir, execCommand string, opts *Con
 		}
 	}
 
+	if err := validateScope(opts.Scope, opts.Users); err != nil {
+		return SystemdServiceConfig{}, err
+	}
+
+	scope := opts.Scope
+	if scope == "" {
+		scope = ScopeSystem
+	}
+
 	// Expand home directory if path starts with ~
 	if strings.HasPrefix(baseDir, "~/") {
 		homeDir, err := os.UserHomeDir()
@@ -139,7 +227,26 @@ func NewSystemdServiceConfig(serviceName, baseDir, execCommand string, opts *Con
 	// Build paths
 	logPath := filepath.Join(baseDir, "logs", "service.log")
 	execPath := filepath.Join(baseDir, execCommand)
-	savePath := filepath.Join("/lib/systemd/system", serviceName+".service")
+
+	wantedBy := "multi-user.target"
+	var savePath string
+	var userPaths []string
+	switch scope {
+	case ScopeUser:
+		wantedBy = "default.target"
+		for _, username := range opts.Users {
+			homeDir, err := userHomeDir(username)
+			if err != nil {
+				return SystemdServiceConfig{}, fmt.Errorf("systemd: resolving home dir for user %q: %w", username, err)
+			}
+			userPaths = append(userPaths, filepath.Join(homeDir, ".config", "systemd", "user", serviceName+".service"))
+		}
+	case ScopeGlobal:
+		wantedBy = "default.target"
+		savePath = filepath.Join("/etc/systemd/user", serviceName+".service")
+	default:
+		savePath = filepath.Join("/lib/systemd/system", serviceName+".service")
+	}
 
 	return SystemdServiceConfig{
 		Unit: struct{ Description string }{
@@ -167,36 +274,23 @@ func NewSystemdServiceConfig(serviceName, baseDir, execCommand string, opts *Con
 			ExecStart:      execPath,
 		},
 		Install: struct{ WantedBy string }{
-			WantedBy: "multi-user.target",
+			WantedBy: wantedBy,
 		},
-		Path: savePath,
-	}
+		Scope: scope,
+		Users: opts.Users,
+		Path:  savePath,
+		Paths: userPaths,
+	}, nil
 }
 
-func (c SystemdServiceConfig) ToString() (string, error) {
-	var sb strings.Builder
-
-	// [Unit] section
-	sb.WriteString("[Unit]\n")
-	sb.WriteString(fmt.Sprintf("Description=%s\n\n", c.Unit.Description))
-
-	// [SystemdService] section
-	sb.WriteString("[SystemdService]\n")
-	sb.WriteString(fmt.Sprintf("Type=%s\n", c.SystemdService.Type))
-	sb.WriteString(fmt.Sprintf("User=%s\n", c.SystemdService.User))
-	sb.WriteString(fmt.Sprintf("Group=%s\n", c.SystemdService.Group))
-	sb.WriteString(fmt.Sprintf("LimitNOFILE=%d\n", c.SystemdService.LimitNOFILE))
-	sb.WriteString(fmt.Sprintf("Restart=%s\n", c.SystemdService.Restart))
-	sb.WriteString(fmt.Sprintf("RestartSec=%s\n", c.SystemdService.RestartSec))
-	sb.WriteString(fmt.Sprintf("StandardOutput=%s\n", c.SystemdService.StandardOutput))
-	sb.WriteString(fmt.Sprintf("StandardError=%s\n", c.SystemdService.StandardError))
-	sb.WriteString(fmt.Sprintf("ExecStart=%s\n\n", c.SystemdService.ExecStart))
-
-	// [Install] section
-	sb.WriteString("[Install]\n")
-	sb.WriteString(fmt.Sprintf("WantedBy=%s\n", c.Install.WantedBy))
-
-	return sb.String(),nil
+// userHomeDir resolves the home directory of an arbitrary username, unlike
+// os.UserHomeDir which only ever reports the calling process's own home.
+func userHomeDir(username string) (string, error) {
+	u, err := user.Lookup(username)
+	if err != nil {
+		return "", err
+	}
+	return u.HomeDir, nil
 }
 
-
+// ToString, ParseUnitFile, and Validate live in render.go.
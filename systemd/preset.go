@@ -0,0 +1,109 @@
+package systemd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// PresetAction is the action a preset line applies to a unit: enable or
+// disable it on first boot.
+type PresetAction string
+
+const (
+	PresetEnable  PresetAction = "enable"
+	PresetDisable PresetAction = "disable"
+)
+
+// Preset is one entry in a systemd preset file. When provisioning several
+// services in one call, the caller usually wants all of them marked
+// enabled atomically rather than issuing N separate `systemctl enable`
+// calls; WritePresetFile covers that in a single file.
+type Preset struct {
+	// Unit is the unit name, e.g. "pocketbase.service". Templated units
+	// (e.g. "boxman@.service") require at least one entry in Instances.
+	Unit   string
+	Action PresetAction
+	// Instances lists the concrete instance names for a templated unit
+	// (the part that goes between "@" and ".service"). Ignored for
+	// non-templated units.
+	Instances []string
+}
+
+// isTemplate reports whether unit is a templated unit definition like
+// "foo@.service", as opposed to a concrete unit or instance.
+func isTemplate(unit string) bool {
+	base := strings.TrimSuffix(unit, filepath.Ext(unit))
+	return strings.HasSuffix(base, "@")
+}
+
+// lines renders the one-or-more preset file lines for this Preset.
+func (p Preset) lines() ([]string, error) {
+	if isTemplate(p.Unit) {
+		if len(p.Instances) == 0 {
+			return nil, fmt.Errorf("systemd: templated unit %q requires at least one instance", p.Unit)
+		}
+		ext := filepath.Ext(p.Unit)
+		base := strings.TrimSuffix(p.Unit, ext)
+		lines := make([]string, 0, len(p.Instances))
+		for _, instance := range p.Instances {
+			lines = append(lines, fmt.Sprintf("%s %s%s%s", p.Action, base, instance, ext))
+		}
+		return lines, nil
+	}
+	return []string{fmt.Sprintf("%s %s", p.Action, p.Unit)}, nil
+}
+
+// DefaultPresetPath returns the conventional path for a boxman-authored
+// preset file at the given priority, e.g. "/etc/systemd/system-preset/50-boxman.preset".
+// Lower priorities are evaluated first, matching systemd's own preset
+// ordering. The priority is zero-padded to two digits, so file-name order
+// matches numeric order for priorities 0 to 99.
+func DefaultPresetPath(priority int) string {
+	return filepath.Join("/etc/systemd/system-preset", fmt.Sprintf("%02d-boxman.preset", priority))
+}
+
+// WritePresetFile renders units into a systemd preset file at path, in the
+// same "enable foo.service" / "disable bar.service" format Ignition's
+// createSystemdPresetFile produces.
+func WritePresetFile(units []Preset, path string) error {
+	var sb strings.Builder
+	for _, u := range units {
+		lines, err := u.lines()
+		if err != nil {
+			return err
+		}
+		for _, line := range lines {
+			sb.WriteString(line)
+			sb.WriteString("\n")
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("systemd: creating %s: %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, []byte(sb.String()), 0o644); err != nil {
+		return fmt.Errorf("systemd: writing %s: %w", path, err)
+	}
+	return nil
+}
+
+// ApplyPresets runs `systemctl preset-all` so every installed preset file
+// (including ones just written by WritePresetFile) takes effect. Older
+// systemd releases without preset-all are handled by falling back to
+// `systemctl preset <unit>` once per unit in units.
+func ApplyPresets(ctx context.Context, units []string) error {
+	if err := exec.CommandContext(ctx, "systemctl", "preset-all").Run(); err == nil {
+		return nil
+	}
+
+	for _, unit := range units {
+		if err := exec.CommandContext(ctx, "systemctl", "preset", unit).Run(); err != nil {
+			return fmt.Errorf("systemd: presetting %q: %w", unit, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package systemd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIsTemplate(t *testing.T) {
+	cases := []struct {
+		unit string
+		want bool
+	}{
+		{"pocketbase.service", false},
+		{"boxman@.service", true},
+		{"boxman@instance.service", false},
+		{"foo@", true},
+		{"foo", false},
+	}
+
+	for _, c := range cases {
+		if got := isTemplate(c.unit); got != c.want {
+			t.Errorf("isTemplate(%q) = %v, want %v", c.unit, got, c.want)
+		}
+	}
+}
+
+func TestPresetLines(t *testing.T) {
+	cases := []struct {
+		name    string
+		preset  Preset
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:   "plain unit",
+			preset: Preset{Unit: "pocketbase.service", Action: PresetEnable},
+			want:   []string{"enable pocketbase.service"},
+		},
+		{
+			name:   "plain unit disable",
+			preset: Preset{Unit: "pocketbase.service", Action: PresetDisable},
+			want:   []string{"disable pocketbase.service"},
+		},
+		{
+			name: "templated unit with multiple instances",
+			preset: Preset{
+				Unit:      "boxman@.service",
+				Action:    PresetEnable,
+				Instances: []string{"alice", "bob"},
+			},
+			want: []string{"enable boxman@alice.service", "enable boxman@bob.service"},
+		},
+		{
+			name:    "templated unit with no instances",
+			preset:  Preset{Unit: "boxman@.service", Action: PresetEnable},
+			wantErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := c.preset.lines()
+			if c.wantErr {
+				if err == nil {
+					t.Fatalf("lines() = %v, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("lines() returned unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("lines() = %v, want %v", got, c.want)
+			}
+		})
+	}
+}
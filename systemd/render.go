@@ -0,0 +1,202 @@
+package systemd
+
+import (
+	"fmt"
+	"os/user"
+	"strconv"
+	"strings"
+
+	"gopkg.in/ini.v1"
+)
+
+var knownTypes = map[string]bool{
+	"simple": true, "forking": true, "oneshot": true,
+	"dbus": true, "notify": true, "idle": true,
+}
+
+var knownRestarts = map[string]bool{
+	"no": true, "always": true, "on-success": true, "on-failure": true,
+	"on-abnormal": true, "on-watchdog": true, "on-abort": true,
+}
+
+// Validate checks that c has everything systemd requires of a service unit
+// before it's written to disk: a non-empty ExecStart, a recognized Type and
+// Restart policy, and a User/Group that actually exist on this system.
+func (c SystemdServiceConfig) Validate() error {
+	if strings.TrimSpace(c.SystemdService.ExecStart) == "" {
+		return fmt.Errorf("systemd: ExecStart is required")
+	}
+	if c.SystemdService.Type != "" && !knownTypes[c.SystemdService.Type] {
+		return fmt.Errorf("systemd: unknown Type %q", c.SystemdService.Type)
+	}
+	if c.SystemdService.Restart != "" && !knownRestarts[c.SystemdService.Restart] {
+		return fmt.Errorf("systemd: unknown Restart %q", c.SystemdService.Restart)
+	}
+	if c.SystemdService.User != "" {
+		if _, err := user.Lookup(c.SystemdService.User); err != nil {
+			return fmt.Errorf("systemd: user %q does not exist: %w", c.SystemdService.User, err)
+		}
+	}
+	if c.SystemdService.Group != "" {
+		if _, err := user.LookupGroup(c.SystemdService.Group); err != nil {
+			return fmt.Errorf("systemd: group %q does not exist: %w", c.SystemdService.Group, err)
+		}
+	}
+	return nil
+}
+
+// addShadowKeys writes every value for key into section, using repeated
+// (shadow) keys so that directives like Environment= or ExecStartPre= that
+// are meaningful when repeated keep every occurrence instead of just the
+// last one.
+func addShadowKeys(section *ini.Section, key string, values []string) error {
+	if len(values) == 0 {
+		return nil
+	}
+	k, err := section.NewKey(key, values[0])
+	if err != nil {
+		return err
+	}
+	for _, v := range values[1:] {
+		k.AddShadow(v)
+	}
+	return nil
+}
+
+// ToString renders c as a unit file. It's backed by gopkg.in/ini.v1 rather
+// than hand-rolled string concatenation so that arbitrary extra directives
+// in c.Extra round-trip correctly, including repeated keys.
+func (c SystemdServiceConfig) ToString() (string, error) {
+	cfg := ini.Empty(ini.LoadOptions{AllowShadows: true})
+
+	unitSec, err := cfg.NewSection("Unit")
+	if err != nil {
+		return "", err
+	}
+	if c.Unit.Description != "" {
+		unitSec.NewKey("Description", c.Unit.Description)
+	}
+	for key, values := range c.Extra["Unit"] {
+		if err := addShadowKeys(unitSec, key, values); err != nil {
+			return "", err
+		}
+	}
+
+	serviceSec, err := cfg.NewSection("Service")
+	if err != nil {
+		return "", err
+	}
+	setIfNonEmpty(serviceSec, "Type", c.SystemdService.Type)
+	setIfNonEmpty(serviceSec, "User", c.SystemdService.User)
+	setIfNonEmpty(serviceSec, "Group", c.SystemdService.Group)
+	if c.SystemdService.LimitNOFILE != 0 {
+		serviceSec.NewKey("LimitNOFILE", strconv.Itoa(c.SystemdService.LimitNOFILE))
+	}
+	setIfNonEmpty(serviceSec, "Restart", c.SystemdService.Restart)
+	setIfNonEmpty(serviceSec, "RestartSec", c.SystemdService.RestartSec)
+	setIfNonEmpty(serviceSec, "StandardOutput", c.SystemdService.StandardOutput)
+	setIfNonEmpty(serviceSec, "StandardError", c.SystemdService.StandardError)
+	setIfNonEmpty(serviceSec, "ExecStart", c.SystemdService.ExecStart)
+	for key, values := range c.Extra["Service"] {
+		if err := addShadowKeys(serviceSec, key, values); err != nil {
+			return "", err
+		}
+	}
+
+	installSec, err := cfg.NewSection("Install")
+	if err != nil {
+		return "", err
+	}
+	setIfNonEmpty(installSec, "WantedBy", c.Install.WantedBy)
+	for key, values := range c.Extra["Install"] {
+		if err := addShadowKeys(installSec, key, values); err != nil {
+			return "", err
+		}
+	}
+
+	var sb strings.Builder
+	if _, err := cfg.WriteTo(&sb); err != nil {
+		return "", fmt.Errorf("systemd: rendering unit file: %w", err)
+	}
+	return sb.String(), nil
+}
+
+func setIfNonEmpty(section *ini.Section, key, value string) {
+	if value != "" {
+		section.NewKey(key, value)
+	}
+}
+
+// ParseUnitFile reads an on-disk unit file back into a SystemdServiceConfig,
+// enabling edit-in-place workflows from the HTTP API: fetch, tweak a field,
+// write back with ToString. Directives in the [Unit], [Service] and [Install]
+// sections that don't map onto a typed field are preserved in Extra,
+// including repeated keys; other sections are not read.
+func ParseUnitFile(path string) (SystemdServiceConfig, error) {
+	cfg, err := ini.LoadSources(ini.LoadOptions{AllowShadows: true}, path)
+	if err != nil {
+		return SystemdServiceConfig{}, fmt.Errorf("systemd: reading %s: %w", path, err)
+	}
+
+	var c SystemdServiceConfig
+	c.Extra = map[string]map[string][]string{}
+
+	if sec, err := cfg.GetSection("Unit"); err == nil {
+		for _, key := range sec.Keys() {
+			if key.Name() == "Description" {
+				c.Unit.Description = key.String()
+				continue
+			}
+			addExtra(c.Extra, "Unit", key)
+		}
+	}
+
+	if sec, err := cfg.GetSection("Service"); err == nil {
+		for _, key := range sec.Keys() {
+			switch key.Name() {
+			case "Type":
+				c.SystemdService.Type = key.String()
+			case "User":
+				c.SystemdService.User = key.String()
+			case "Group":
+				c.SystemdService.Group = key.String()
+			case "LimitNOFILE":
+				c.SystemdService.LimitNOFILE, _ = key.Int()
+			case "Restart":
+				c.SystemdService.Restart = key.String()
+			case "RestartSec":
+				c.SystemdService.RestartSec = key.String()
+			case "StandardOutput":
+				c.SystemdService.StandardOutput = key.String()
+			case "StandardError":
+				c.SystemdService.StandardError = key.String()
+			case "ExecStart":
+				c.SystemdService.ExecStart = key.String()
+			default:
+				addExtra(c.Extra, "Service", key)
+			}
+		}
+	}
+
+	if sec, err := cfg.GetSection("Install"); err == nil {
+		for _, key := range sec.Keys() {
+			if key.Name() == "WantedBy" {
+				c.Install.WantedBy = key.String()
+				continue
+			}
+			addExtra(c.Extra, "Install", key)
+		}
+	}
+
+	c.Path = path
+	return c, nil
+}
+
+// addExtra records every value of key (including shadow values) under
+// extra[section][key.Name()].
+func addExtra(extra map[string]map[string][]string, section string, key *ini.Key) {
+	if extra[section] == nil {
+		extra[section] = make(map[string][]string)
+	}
+	extra[section][key.Name()] = key.ValueWithShadows()
+}
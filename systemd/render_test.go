@@ -0,0 +1,71 @@
+package systemd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"gopkg.in/ini.v1"
+)
+
+func TestToStringParseUnitFileRoundTrip(t *testing.T) {
+	cfg := SystemdServiceConfig{}
+	cfg.Unit.Description = "boxman-managed service"
+	cfg.SystemdService.Type = "simple"
+	cfg.SystemdService.User = "pocketbase"
+	cfg.SystemdService.Group = "pocketbase"
+	cfg.SystemdService.LimitNOFILE = 4096
+	cfg.SystemdService.Restart = "always"
+	cfg.SystemdService.RestartSec = "5s"
+	cfg.SystemdService.ExecStart = "/root/pb/pocketbase serve yourdomain.com"
+	cfg.Install.WantedBy = "multi-user.target"
+	cfg.Extra = map[string]map[string][]string{
+		"Service": {
+			"Environment": {"FOO=bar", "BAZ=qux"},
+		},
+	}
+
+	rendered, err := cfg.ToString()
+	if err != nil {
+		t.Fatalf("ToString() returned unexpected error: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "pocketbase.service")
+	if err := os.WriteFile(path, []byte(rendered), 0o644); err != nil {
+		t.Fatalf("writing rendered unit: %v", err)
+	}
+
+	got, err := ParseUnitFile(path)
+	if err != nil {
+		t.Fatalf("ParseUnitFile(%q) returned unexpected error: %v", path, err)
+	}
+
+	got.Path = ""
+	want := cfg
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseUnitFile round trip = %+v, want %+v", got, want)
+	}
+}
+
+func TestAddShadowKeys(t *testing.T) {
+	cfg := ini.Empty(ini.LoadOptions{AllowShadows: true})
+	sec, err := cfg.NewSection("Service")
+	if err != nil {
+		t.Fatalf("NewSection() returned unexpected error: %v", err)
+	}
+
+	if err := addShadowKeys(sec, "Environment", []string{"FOO=bar", "BAZ=qux"}); err != nil {
+		t.Fatalf("addShadowKeys() returned unexpected error: %v", err)
+	}
+
+	key, err := sec.GetKey("Environment")
+	if err != nil {
+		t.Fatalf("GetKey() returned unexpected error: %v", err)
+	}
+	got := key.ValueWithShadows()
+	want := []string{"FOO=bar", "BAZ=qux"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ValueWithShadows() = %v, want %v", got, want)
+	}
+}
@@ -3,57 +3,141 @@ package systemd
 import (
 	"bufio"
 	"bytes"
+	"context"
+	"fmt"
 	"log"
 	"os/exec"
+	"os/user"
 	"strings"
 )
 
 type Service struct {
-    Name        string
-    Unit        string
-    ActiveState string
-    SubState    string
-    LoadState   string
-    Path        string
+	Name        string
+	Unit        string
+	ActiveState string
+	SubState    string
+	LoadState   string
+	Path        string
 }
 
+// GetSystemDServices lists active service units, optionally filtered to
+// names containing partialName. It prefers the systemd D-Bus API (see
+// Client.ListUnits), which returns typed unit names and states instead of
+// parsed text; it falls back to exec.Command("systemctl", ...) scraping only
+// when the D-Bus connection can't be established, e.g. inside a minimal
+// container with no system bus, or when listing units over D-Bus fails.
+// This function is reachable from the
+// GET /services HTTP handler, so a systemctl failure here returns an empty
+// result rather than killing the whole process.
 func GetSystemDServices(partialName string) []Service {
-    cmd := exec.Command("systemctl", "list-units", "--type=service", "--state=active")
-    output, err := cmd.CombinedOutput()
-    if err != nil {
-        log.Fatalf("Could not run systemctl: %v", err)
-    }
-    scanner := bufio.NewScanner(bytes.NewReader(output))
-    scanner.Split(bufio.ScanLines)
-    services := []Service{}
-    for scanner.Scan() {
-        line := scanner.Text()
-        if strings.HasPrefix(line, "UNIT") {
-            continue
-        }
-        fields := strings.Fields(line)
-        if len(fields) < 3 {
-            continue
-        }
-        if partialName != "" && !strings.Contains(fields[0], partialName) {
-            continue
-        }
-        service := Service{
-            Name:        fields[0],
-            Unit:        fields[1],
-            ActiveState: fields[2],
-        }
-        if len(fields) > 3 {
-            service.SubState = fields[3]
-        }
-        if len(fields) > 4 {
-            service.LoadState = fields[4]
-        }
-        service.Path = "/etc/systemd/system/" + service.Name + ".service"
-        services = append(services, service)
-    }
-    return services
+	ctx := context.Background()
+	client, err := NewClient(ctx)
+	if err == nil {
+		defer client.Close()
+		services, err := client.ListUnits(ctx, partialName)
+		if err == nil {
+			return services
+		}
+		log.Printf("systemd: D-Bus ListUnits failed, falling back to systemctl: %v", err)
+	} else {
+		log.Printf("systemd: D-Bus unavailable, falling back to systemctl: %v", err)
+	}
+
+	cmd := exec.Command("systemctl", "list-units", "--type=service", "--state=active")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		log.Printf("systemd: could not run systemctl: %v", err)
+		return []Service{}
+	}
+	services := parseSystemctlListUnits(output, partialName)
+	for i := range services {
+		services[i].Path = "/etc/systemd/system/" + services[i].Name + ".service"
+	}
+	return services
+}
+
+// RunUserSystemctl runs `systemctl --user <args...>` as username. It sets
+// XDG_RUNTIME_DIR by invoking the `env` utility as the command sudo execs,
+// rather than via cmd.Env: sudo's default env_reset policy strips inherited
+// environment variables unless a sudoers env_keep entry or --preserve-env
+// allows them through, so setting it on the *sudo* process's environment
+// would silently not reach systemctl on a stock sudo config. Passing it as
+// an argument to `env` sidesteps that policy entirely.
+func RunUserSystemctl(username string, args ...string) ([]byte, error) {
+	u, err := user.Lookup(username)
+	if err != nil {
+		return nil, fmt.Errorf("systemd: looking up user %q: %w", username, err)
+	}
+
+	cmdArgs := append([]string{
+		"-u", username,
+		"env", fmt.Sprintf("XDG_RUNTIME_DIR=/run/user/%s", u.Uid),
+		"systemctl", "--user",
+	}, args...)
+	return exec.Command("sudo", cmdArgs...).CombinedOutput()
 }
 
+// GetUserSystemDServices is the --user analogue of GetSystemDServices: for
+// each of the given target users it runs `systemctl --user list-units`
+// against that user's own manager instance and returns the matched services
+// keyed by username. A user whose manager instance can't be reached (e.g. no
+// active login session, so no /run/user/<uid> bus) is logged and skipped
+// rather than failing the whole call.
+func GetUserSystemDServices(partialName string, users []string) map[string][]Service {
+	servicesByUser := make(map[string][]Service, len(users))
+	for _, username := range users {
+		u, err := user.Lookup(username)
+		if err != nil {
+			log.Printf("systemd: could not look up user %q: %v", username, err)
+			continue
+		}
 
+		output, err := RunUserSystemctl(username, "list-units", "--type=service", "--state=active")
+		if err != nil {
+			log.Printf("systemd: could not run systemctl --user for %q: %v", username, err)
+			continue
+		}
 
+		services := parseSystemctlListUnits(output, partialName)
+		for i := range services {
+			services[i].Path = u.HomeDir + "/.config/systemd/user/" + services[i].Name + ".service"
+		}
+		servicesByUser[username] = services
+	}
+	return servicesByUser
+}
+
+// parseSystemctlListUnits parses the whitespace-delimited table produced by
+// `systemctl list-units` / `systemctl --user list-units`, optionally
+// filtering to unit names containing partialName.
+func parseSystemctlListUnits(output []byte, partialName string) []Service {
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	scanner.Split(bufio.ScanLines)
+	services := []Service{}
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "UNIT") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			continue
+		}
+		if partialName != "" && !strings.Contains(fields[0], partialName) {
+			continue
+		}
+		service := Service{
+			Name:        fields[0],
+			Unit:        fields[1],
+			ActiveState: fields[2],
+		}
+		if len(fields) > 3 {
+			service.SubState = fields[3]
+		}
+		if len(fields) > 4 {
+			service.LoadState = fields[4]
+		}
+		services = append(services, service)
+	}
+	return services
+}